@@ -0,0 +1,200 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ErrOptimisticLock is returned by UpdateById when entity has a
+// gplus:"version" tagged field and the WHERE version = ? clause this
+// package appends matched zero rows, meaning the row was changed (or
+// deleted) concurrently since the version value was read. Update(q) has no
+// single entity to read a "current" version from, so it doesn't manage
+// version automatically and never returns this error; see updateOn.
+var ErrOptimisticLock = errors.New("gplus: optimistic lock conflict, row was modified concurrently")
+
+// Struct tag values recognised on the "gplus" tag for automatic columns.
+const (
+	tagVersion   = "version"
+	tagCreatedBy = "created_by"
+	tagUpdatedBy = "updated_by"
+	tagTenantID  = "tenant_id"
+)
+
+type auditContextKey string
+
+const (
+	createdByKey auditContextKey = "gplus:created_by"
+	updatedByKey auditContextKey = "gplus:updated_by"
+	tenantIDKey  auditContextKey = "gplus:tenant_id"
+)
+
+// WithCreatedBy carries the current actor on ctx so InsertCtx can populate
+// any gplus:"created_by" tagged field on the inserted entity.
+func WithCreatedBy(ctx context.Context, createdBy any) context.Context {
+	return context.WithValue(ctx, createdByKey, createdBy)
+}
+
+// WithUpdatedBy carries the current actor on ctx so InsertCtx/UpdateCtx/
+// UpdateByIdCtx can populate any gplus:"updated_by" tagged field.
+func WithUpdatedBy(ctx context.Context, updatedBy any) context.Context {
+	return context.WithValue(ctx, updatedByKey, updatedBy)
+}
+
+// WithTenantID carries the current tenant on ctx so InsertCtx/UpdateCtx/
+// UpdateByIdCtx can populate any gplus:"tenant_id" tagged field.
+func WithTenantID(ctx context.Context, tenantID any) context.Context {
+	return context.WithValue(ctx, tenantIDKey, tenantID)
+}
+
+// parseSchema parses entity through gorm so the returned statement's
+// Schema.Fields carry the table's real column names (DBName) rather than a
+// guessed snake_case conversion of the Go field name. db must be the
+// *gorm.DB actually in play for the call (Session's, a transaction's, or
+// gormDb) rather than the package global, so this also works for callers
+// that never call Init (Session/WithDB-only usage).
+func parseSchema(db *gorm.DB, entity any) *gorm.Statement {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return nil
+	}
+	return stmt
+}
+
+func findGplusField(stmt *gorm.Statement, tag string) (dbName string, index []int, ok bool) {
+	if stmt == nil || stmt.Schema == nil {
+		return "", nil, false
+	}
+	for _, f := range stmt.Schema.Fields {
+		if f.StructField.Tag.Get("gplus") == tag {
+			return f.DBName, f.StructField.Index, true
+		}
+	}
+	return "", nil, false
+}
+
+// gplusColumn resolves the DB column name tagged gplus:"tag" on T, without
+// needing an entity instance (used when building WHERE/SET clauses from a
+// Query[T] rather than a concrete struct value).
+func gplusColumn[T any](db *gorm.DB, tag string) (string, bool) {
+	stmt := parseSchema(db, new(T))
+	col, _, ok := findGplusField(stmt, tag)
+	return col, ok
+}
+
+func setTaggedField(db *gorm.DB, entity any, tag string, value any) {
+	stmt := parseSchema(db, entity)
+	_, index, ok := findGplusField(stmt, tag)
+	if !ok || value == nil {
+		return
+	}
+
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	field := v.FieldByIndex(index)
+	if !field.CanSet() {
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Type().ConvertibleTo(field.Type()):
+		field.Set(rv.Convert(field.Type()))
+	}
+}
+
+// applyAuditFields populates entity's gplus:"created_by"/"updated_by"/
+// "tenant_id" tagged fields from values carried on ctx by WithCreatedBy/
+// WithUpdatedBy/WithTenantID. onInsert controls whether created_by (in
+// addition to updated_by/tenant_id) is populated. db must be the *gorm.DB
+// resolved for this call (e.g. via dbFromContext), not the package global.
+func applyAuditFields(ctx context.Context, db *gorm.DB, entity any, onInsert bool) {
+	if onInsert {
+		if v := ctx.Value(createdByKey); v != nil {
+			setTaggedField(db, entity, tagCreatedBy, v)
+		}
+	}
+	if v := ctx.Value(updatedByKey); v != nil {
+		setTaggedField(db, entity, tagUpdatedBy, v)
+	}
+	if v := ctx.Value(tenantIDKey); v != nil {
+		setTaggedField(db, entity, tagTenantID, v)
+	}
+}
+
+// applyAuditToUpdateMap stages updated_by/tenant_id values carried on ctx
+// into q.UpdateMap, for the Update(q) path where there's no single entity
+// instance to set fields on directly. db must be the *gorm.DB resolved for
+// this call, not the package global.
+func applyAuditToUpdateMap[T any](ctx context.Context, db *gorm.DB, q *Query[T]) {
+	if v := ctx.Value(updatedByKey); v != nil {
+		if col, ok := gplusColumn[T](db, tagUpdatedBy); ok {
+			q.Set(col, v)
+		}
+	}
+	if v := ctx.Value(tenantIDKey); v != nil {
+		if col, ok := gplusColumn[T](db, tagTenantID); ok {
+			q.Set(col, v)
+		}
+	}
+}
+
+// applyOptimisticLock appends "WHERE version = ?" (the entity's current
+// value) to db when entity has a gplus:"version" tagged field, and bumps
+// that field on entity by one so the subsequent Updates call also emits
+// "SET version = <current+1>". It reports whether entity carried such a
+// field, so the caller knows whether a zero RowsAffected means a stale
+// version rather than simply "no such row".
+func applyOptimisticLock(db *gorm.DB, entity any) (*gorm.DB, bool) {
+	stmt := parseSchema(db, entity)
+	dbName, index, ok := findGplusField(stmt, tagVersion)
+	if !ok {
+		return db, false
+	}
+
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	field := v.FieldByIndex(index)
+
+	db = db.Where(dbName+" = ?", field.Interface())
+	bumpVersion(field)
+	return db, true
+}
+
+func bumpVersion(field reflect.Value) {
+	if !field.CanSet() {
+		return
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	}
+}
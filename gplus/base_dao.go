@@ -19,14 +19,24 @@ package gplus
 
 import (
 	"github.com/acmestack/gorm-plus/constants"
+	"github.com/acmestack/gorm-plus/gplus/dialect"
 	"gorm.io/gorm"
 )
 
 var gormDb *gorm.DB
 var defaultBatchSize = 1000
+var currentDialect dialect.Dialect
 
 func Init(db *gorm.DB) {
 	gormDb = db
+	currentDialect = dialect.Detect(db.Dialector.Name())
+}
+
+// UseDialect overrides the dialect auto-detected from the DB driver at
+// Init, for drivers gorm reports under a name dialect doesn't recognize
+// (e.g. an OpenGauss driver that is Postgres-wire-compatible).
+func UseDialect(d dialect.Dialect) {
+	currentDialect = d
 }
 
 type Page[T any] struct {
@@ -40,81 +50,155 @@ func NewPage[T any](current, size int) *Page[T] {
 	return &Page[T]{Current: current, Size: size}
 }
 
+// The package-level functions below operate against the global gormDb set
+// by Init. Each delegates to an unexported, db-parameterized twin so that
+// Session (see session.go) can offer the exact same method set bound to an
+// explicit, possibly per-request *gorm.DB instead.
+
 func Insert[T any](entity *T) *gorm.DB {
-	resultDb := gormDb.Create(entity)
-	return resultDb
+	return insertOn(gormDb, entity)
 }
 
 func InsertBatch[T any](entities []*T) *gorm.DB {
-	if len(entities) == 0 {
-		return gormDb
-	}
-	resultDb := gormDb.CreateInBatches(entities, defaultBatchSize)
-	return resultDb
+	return insertBatchOn(gormDb, entities, defaultBatchSize)
 }
 
 func InsertBatchSize[T any](entities []*T, batchSize int) *gorm.DB {
-	if len(entities) == 0 {
-		return gormDb
-	}
 	if batchSize <= 0 {
 		batchSize = defaultBatchSize
 	}
-	resultDb := gormDb.CreateInBatches(entities, batchSize)
-	return resultDb
+	return insertBatchOn(gormDb, entities, batchSize)
 }
 
 func DeleteById[T any, K PrimaryKey](id K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdOn[T](gormDb, id, primaryKeyColumn...)
+}
+
+func DeleteByIds[T any, K PrimaryKey](ids []K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdsOn[T](gormDb, ids, primaryKeyColumn...)
+}
+
+func Delete[T any](q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	return deleteOn(gormDb, applyScopes(q, scopes))
+}
+
+func UpdateById[T any, K PrimaryKey](entity *T, id K, primaryKeyColumn ...string) *gorm.DB {
+	return updateByIdOn(gormDb, entity, id, primaryKeyColumn...)
+}
+
+func Update[T any](q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	return updateOn(gormDb, applyScopes(q, scopes))
+}
+
+func SelectById[T any, K PrimaryKey](id K) (*T, *gorm.DB) {
+	return selectByIdOn[T, K](gormDb, id)
+}
+
+func SelectByIds[T any, K PrimaryKey](ids []K, primaryKeyColumn ...string) ([]*T, *gorm.DB) {
+	return selectByIdsOn[T, K](gormDb, ids, primaryKeyColumn...)
+}
+
+func SelectOne[T any](q *Query[T]) (*T, *gorm.DB) {
+	return selectOneOn(gormDb, q)
+}
+
+func SelectList[T any](q *Query[T], scopes ...Scope[T]) ([]*T, *gorm.DB) {
+	return selectListOn(gormDb, applyScopes(q, scopes))
+}
+
+func SelectListModel[T any, R any](q *Query[T]) ([]*R, *gorm.DB) {
+	return selectListModelOn[T, R](gormDb, q)
+}
+
+func SelectPage[T any](page *Page[T], q *Query[T], scopes ...Scope[T]) (*Page[T], *gorm.DB) {
+	return selectPageOn(gormDb, page, applyScopes(q, scopes))
+}
+
+func SelectPageModel[T any, R any](page *Page[R], q *Query[T]) (*Page[R], *gorm.DB) {
+	return selectPageModelOn[T, R](gormDb, page, q)
+}
+
+func SelectCount[T any](q *Query[T]) (int64, *gorm.DB) {
+	return selectCountOn(gormDb, q)
+}
+
+func insertOn[T any](db *gorm.DB, entity *T) *gorm.DB {
+	resultDb := db.Create(entity)
+	return resultDb
+}
+
+func insertBatchOn[T any](db *gorm.DB, entities []*T, batchSize int) *gorm.DB {
+	if len(entities) == 0 {
+		return db
+	}
+	resultDb := db.CreateInBatches(entities, batchSize)
+	return resultDb
+}
+
+func deleteByIdOn[T any, K PrimaryKey](db *gorm.DB, id K, primaryKeyColumn ...string) *gorm.DB {
 	var entity T
-	resultDb := gormDb.Where(getPKColumn(primaryKeyColumn), id).Delete(&entity)
+	resultDb := db.Where(getPKColumn(primaryKeyColumn), id).Delete(&entity)
 	return resultDb
 }
 
-func DeleteByIds[T any, K PrimaryKey](ids []K, primaryKeyColumn ...string) *gorm.DB {
+func deleteByIdsOn[T any, K PrimaryKey](db *gorm.DB, ids []K, primaryKeyColumn ...string) *gorm.DB {
 	if len(ids) == 0 {
-		return gormDb
+		return db
 	}
 
 	q := NewQuery[T]()
 	q.In(getPKColumn(primaryKeyColumn), ids)
-	resultDb := Delete[T](q)
+	resultDb := deleteOn(db, q)
 	return resultDb
 }
 
-func Delete[T any](q *Query[T]) *gorm.DB {
+func deleteOn[T any](db *gorm.DB, q *Query[T]) *gorm.DB {
 	var entity T
-	resultDb := gormDb.Where(q.QueryBuilder.String(), q.QueryArgs...).Delete(&entity)
+	resultDb := db.Where(q.QueryBuilder.String(), q.QueryArgs...).Delete(&entity)
 	return resultDb
 }
 
-func UpdateById[T any, K PrimaryKey](entity *T, id K, primaryKeyColumn ...string) *gorm.DB {
-	resultDb := gormDb.Model(&entity).Where(getPKColumn(primaryKeyColumn), id).Updates(entity)
+func updateByIdOn[T any, K PrimaryKey](db *gorm.DB, entity *T, id K, primaryKeyColumn ...string) *gorm.DB {
+	tx := db.Model(&entity).Where(getPKColumn(primaryKeyColumn), id)
+	tx, locked := applyOptimisticLock(tx, entity)
+
+	resultDb := tx.Updates(entity)
+	if locked && resultDb.Error == nil && resultDb.RowsAffected == 0 {
+		resultDb.Error = ErrOptimisticLock
+	}
 	return resultDb
 }
 
-func Update[T any](q *Query[T]) *gorm.DB {
-	resultDb := gormDb.Model(new(T)).Where(q.QueryBuilder.String(), q.QueryArgs...).Updates(&q.UpdateMap)
+// updateOn does not manage gplus:"version" automatically: unlike
+// UpdateById, q has no single entity to compare a known-current version
+// against, so there's no way to tell a genuine version conflict apart from
+// "no row matched q's filters" - auto-bumping the version and reporting
+// ErrOptimisticLock for either would be misleading. Optimistic locking via
+// Update(q) is still possible, just explicit: q.Eq(versionColumn, current)
+// and q.Set(versionColumn, current+1) at the call site.
+func updateOn[T any](db *gorm.DB, q *Query[T]) *gorm.DB {
+	resultDb := db.Model(new(T)).Where(q.QueryBuilder.String(), q.QueryArgs...).Updates(&q.UpdateMap)
 	return resultDb
 }
 
-func SelectById[T any, K PrimaryKey](id K) (*T, *gorm.DB) {
+func selectByIdOn[T any, K PrimaryKey](db *gorm.DB, id K) (*T, *gorm.DB) {
 	var entity T
-	resultDb := gormDb.Take(&entity, id)
+	resultDb := db.Take(&entity, id)
 	if resultDb.RowsAffected == 0 {
 		return nil, resultDb
 	}
 	return &entity, resultDb
 }
 
-func SelectByIds[T any, K PrimaryKey](ids []K, primaryKeyColumn ...string) ([]*T, *gorm.DB) {
+func selectByIdsOn[T any, K PrimaryKey](db *gorm.DB, ids []K, primaryKeyColumn ...string) ([]*T, *gorm.DB) {
 	q := NewQuery[T]()
 	q.In(getPKColumn(primaryKeyColumn), ids)
-	return SelectList[T](q)
+	return selectListOn(db, q)
 }
 
-func SelectOne[T any](q *Query[T]) (*T, *gorm.DB) {
+func selectOneOn[T any](db *gorm.DB, q *Query[T]) (*T, *gorm.DB) {
 	var entity T
-	resultDb := buildCondition(q)
+	resultDb := buildCondition(db, q)
 	resultDb.Take(&entity)
 	if resultDb.RowsAffected == 0 {
 		return nil, resultDb
@@ -122,49 +206,49 @@ func SelectOne[T any](q *Query[T]) (*T, *gorm.DB) {
 	return &entity, resultDb
 }
 
-func SelectList[T any](q *Query[T]) ([]*T, *gorm.DB) {
-	resultDb := buildCondition(q)
+func selectListOn[T any](db *gorm.DB, q *Query[T]) ([]*T, *gorm.DB) {
+	resultDb := buildCondition(db, q)
 	var results []*T
 	resultDb.Find(&results)
 	return results, resultDb
 }
 
-func SelectListModel[T any, R any](q *Query[T]) ([]*R, *gorm.DB) {
-	resultDb := buildCondition(q)
+func selectListModelOn[T any, R any](db *gorm.DB, q *Query[T]) ([]*R, *gorm.DB) {
+	resultDb := buildCondition(db, q)
 	var results []*R
 	resultDb.Scan(&results)
 	return results, resultDb
 }
 
-func SelectPage[T any](page *Page[T], q *Query[T]) (*Page[T], *gorm.DB) {
-	total, countDb := SelectCount[T](q)
+func selectPageOn[T any](db *gorm.DB, page *Page[T], q *Query[T]) (*Page[T], *gorm.DB) {
+	total, countDb := selectCountOn(db, q)
 	if countDb.Error != nil {
 		return page, countDb
 	}
 	page.Total = total
-	resultDb := buildCondition(q)
+	resultDb := buildCondition(db, q)
 	var results []*T
 	resultDb.Scopes(paginate(page)).Find(&results)
 	page.Records = results
 	return page, resultDb
 }
 
-func SelectPageModel[T any, R any](page *Page[R], q *Query[T]) (*Page[R], *gorm.DB) {
-	total, countDb := SelectCount[T](q)
+func selectPageModelOn[T any, R any](db *gorm.DB, page *Page[R], q *Query[T]) (*Page[R], *gorm.DB) {
+	total, countDb := selectCountOn(db, q)
 	if countDb.Error != nil {
 		return page, countDb
 	}
 	page.Total = total
-	resultDb := buildCondition(q)
+	resultDb := buildCondition(db, q)
 	var results []*R
 	resultDb.Scopes(paginate(page)).Scan(&results)
 	page.Records = results
 	return page, resultDb
 }
 
-func SelectCount[T any](q *Query[T]) (int64, *gorm.DB) {
+func selectCountOn[T any](db *gorm.DB, q *Query[T]) (int64, *gorm.DB) {
 	var count int64
-	resultDb := buildCondition(q)
+	resultDb := buildCondition(db, q)
 	resultDb.Count(&count)
 	return count, resultDb
 }
@@ -184,8 +268,8 @@ func paginate[T any](p *Page[T]) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
-func buildCondition[T any](q *Query[T]) *gorm.DB {
-	resultDb := gormDb.Model(new(T))
+func buildCondition[T any](db *gorm.DB, q *Query[T]) *gorm.DB {
+	resultDb := db.Model(new(T))
 	if q != nil {
 		if len(q.DistinctColumns) > 0 {
 			resultDb.Distinct(q.DistinctColumns)
@@ -195,6 +279,18 @@ func buildCondition[T any](q *Query[T]) *gorm.DB {
 			resultDb.Select(q.SelectColumns)
 		}
 
+		if len(q.OmitColumns) > 0 {
+			resultDb.Omit(q.OmitColumns...)
+		}
+
+		for _, preload := range q.PreloadAssociations {
+			resultDb.Preload(preload.Association, preload.Conditions...)
+		}
+
+		for _, join := range q.JoinsAssociations {
+			resultDb.Joins(join)
+		}
+
 		if q.QueryBuilder.Len() > 0 {
 
 			if q.AndBracketBuilder.Len() > 0 {
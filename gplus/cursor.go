@@ -0,0 +1,212 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CursorColumn describes one column of a keyset cursor. Desc must match the
+// ORDER BY direction used for that column so the generated tuple comparison
+// stays consistent with the ordering.
+type CursorColumn struct {
+	Column string
+	Desc   bool
+}
+
+// CursorPage is the keyset equivalent of Page[T]: instead of an offset it
+// carries an opaque cursor pointing at the last row of the page, which the
+// caller feeds back in to fetch the next one.
+type CursorPage[T any] struct {
+	Size       int
+	Records    []*T
+	NextCursor string
+	PrevCursor string
+	HasNext    bool
+	HasPrev    bool
+}
+
+// NewCursorPage creates an empty CursorPage of the given page size.
+func NewCursorPage[T any](size int) *CursorPage[T] {
+	return &CursorPage[T]{Size: size}
+}
+
+// SelectCursorPage performs keyset pagination on one or more sortable
+// columns instead of OFFSET/LIMIT. cursor is the opaque value returned as
+// NextCursor by a previous call, or "" for the first page. Pagination only
+// runs forward: a cursor always resumes after the row it encodes, so passing
+// back PrevCursor (the key of a page's first row) returns the rows after that
+// row, not the page before it. Any
+// Where/Order conditions already set on q are preserved; the cursor
+// condition and cursor columns' ORDER BY are appended on top of them.
+func SelectCursorPage[T any](cp *CursorPage[T], q *Query[T], cursor string, columns ...CursorColumn) (*CursorPage[T], *gorm.DB) {
+	return selectCursorPageOn(gormDb, cp, q, cursor, columns...)
+}
+
+func selectCursorPageOn[T any](db *gorm.DB, cp *CursorPage[T], q *Query[T], cursor string, columns ...CursorColumn) (*CursorPage[T], *gorm.DB) {
+	if len(columns) == 0 {
+		var entity T
+		return cp, db.Model(&entity)
+	}
+
+	resultDb := buildCondition(db, q)
+
+	if cursor != "" {
+		values, err := decodeCursor(cursor)
+		if err != nil {
+			var entity T
+			errDb := db.Model(&entity)
+			errDb.AddError(fmt.Errorf("gplus: invalid cursor: %w", err))
+			return cp, errDb
+		}
+		cond, condArgs := cursorCondition(columns, values)
+		resultDb = resultDb.Where(cond, condArgs...)
+		cp.HasPrev = true
+	}
+
+	for _, c := range columns {
+		if c.Desc {
+			resultDb = resultDb.Order(c.Column + " DESC")
+		} else {
+			resultDb = resultDb.Order(c.Column + " ASC")
+		}
+	}
+
+	size := cp.Size
+	if size <= 0 {
+		size = 10
+	}
+
+	// Fetch one extra row so we know whether a next page exists without a
+	// separate COUNT query.
+	var results []*T
+	resultDb = resultDb.Limit(size + 1).Find(&results)
+
+	cp.HasNext = len(results) > size
+	if cp.HasNext {
+		results = results[:size]
+	}
+	cp.Records = results
+
+	if len(results) > 0 {
+		last := results[len(results)-1]
+		cp.NextCursor = encodeCursor(cursorValues(db, last, columns))
+		first := results[0]
+		cp.PrevCursor = encodeCursor(cursorValues(db, first, columns))
+	}
+
+	return cp, resultDb
+}
+
+// cursorCondition builds the resume condition for the given cursor values
+// as a per-column OR-chain:
+//
+//	(c0 op0 ?) OR (c0 = ? AND c1 op1 ?) OR (c0 = ? AND c1 = ? AND c2 op2 ?) ...
+//
+// where opN is "<" if columns[N].Desc, ">" otherwise - each column's Desc
+// is independent, so mixed-direction keysets (e.g. created_at DESC, id ASC
+// as tiebreaker) resume correctly. A single tuple comparison like
+// "(c0, c1) > (?, ?)" only works when every column sorts the same
+// direction: the DB expands it as "c0 > ? OR (c0 = ? AND c1 > ?)", which is
+// wrong the moment a later column's comparison needs the opposite operator.
+func cursorCondition(columns []CursorColumn, values []any) (string, []any) {
+	clauses := make([]string, len(columns))
+	var args []any
+	for i, c := range columns {
+		var clause strings.Builder
+		for j := 0; j < i; j++ {
+			if j > 0 {
+				clause.WriteString(" AND ")
+			}
+			clause.WriteString(columns[j].Column + " = ?")
+			args = append(args, values[j])
+		}
+		if i > 0 {
+			clause.WriteString(" AND ")
+		}
+		op := ">"
+		if c.Desc {
+			op = "<"
+		}
+		clause.WriteString(c.Column + " " + op + " ?")
+		args = append(args, values[i])
+		clauses[i] = "(" + clause.String() + ")"
+	}
+	return strings.Join(clauses, " OR "), args
+}
+
+func cursorValues(db *gorm.DB, record any, columns []CursorColumn) []any {
+	m, err := toFieldMap(db, record)
+	if err != nil {
+		return nil
+	}
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = m[c.Column]
+	}
+	return values
+}
+
+// toFieldMap maps a model's DB column names to their current values using
+// gorm's own schema parser, so callers can read out cursor columns without
+// caring how they're named on the Go struct.
+func toFieldMap(db *gorm.DB, record any) (map[string]any, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	m := make(map[string]any, len(stmt.Schema.Fields))
+	for _, f := range stmt.Schema.Fields {
+		value, _ := f.ValueOf(context.Background(), v)
+		m[f.DBName] = value
+	}
+	return m, nil
+}
+
+func encodeCursor(values []any) string {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(cursor string) ([]any, error) {
+	b, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var values []any
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dialect wraps the handful of SQL upsert dialects gplus needs to
+// support (MySQL, Postgres, SQLite, SQL Server) behind a single Dialect
+// interface, so gplus's CRUD functions can emit dialect-specific UPSERT /
+// RETURNING SQL without sprinkling driver-name checks across gplus itself.
+package dialect
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Name identifies a gorm Dialector by the string Dialector.Name() returns.
+type Name string
+
+const (
+	MySQL     Name = "mysql"
+	Postgres  Name = "postgres"
+	SQLite    Name = "sqlite"
+	SQLServer Name = "sqlserver"
+)
+
+// Dialect emits the dialect-specific clauses gplus needs for upsert-style
+// writes. Implementations should be stateless and safe for concurrent use.
+type Dialect interface {
+	Name() Name
+
+	// SupportsReturning reports whether Returning can populate
+	// auto-generated keys back into the entity after an insert.
+	SupportsReturning() bool
+
+	// Upsert applies an "insert, update on conflict" clause for the given
+	// conflict (unique/primary key) columns and the columns to overwrite.
+	Upsert(db *gorm.DB, conflictColumns, updateColumns []string) *gorm.DB
+
+	// Ignore applies an "insert, skip silently on conflict" clause.
+	Ignore(db *gorm.DB, conflictColumns []string) *gorm.DB
+
+	// Returning applies a RETURNING clause (or its equivalent) for the
+	// given columns, so inserted values can be scanned back into entities.
+	Returning(db *gorm.DB, columns ...string) *gorm.DB
+}
+
+// onConflictDialect covers every driver gorm's portable clause.OnConflict
+// already translates correctly: MySQL's ON DUPLICATE KEY UPDATE, and
+// Postgres/SQLite's ON CONFLICT ... DO UPDATE/DO NOTHING.
+type onConflictDialect struct {
+	name              Name
+	supportsReturning bool
+}
+
+func (d onConflictDialect) Name() Name { return d.name }
+
+func (d onConflictDialect) SupportsReturning() bool { return d.supportsReturning }
+
+func (d onConflictDialect) Upsert(db *gorm.DB, conflictColumns, updateColumns []string) *gorm.DB {
+	return db.Clauses(clause.OnConflict{
+		Columns:   toColumns(conflictColumns),
+		DoUpdates: clause.AssignmentColumns(updateColumns),
+	})
+}
+
+func (d onConflictDialect) Ignore(db *gorm.DB, conflictColumns []string) *gorm.DB {
+	return db.Clauses(clause.OnConflict{
+		Columns:   toColumns(conflictColumns),
+		DoNothing: true,
+	})
+}
+
+func (d onConflictDialect) Returning(db *gorm.DB, columns ...string) *gorm.DB {
+	if !d.supportsReturning || len(columns) == 0 {
+		return db
+	}
+	return db.Clauses(clause.Returning{Columns: toColumns(columns)})
+}
+
+// errSQLServerUpsertUnsupported is returned by sqlServerDialect's Upsert and
+// Ignore. SQL Server has no ON CONFLICT/ON DUPLICATE KEY equivalent gorm's
+// clause.OnConflict can translate to; the real fix is a MERGE statement
+// built against the target table, match columns, and update assignments,
+// which this package doesn't generate yet. Silently reusing clause.OnConflict
+// here would compile but emit a plain INSERT, which duplicate-key errors on
+// conflict instead of upserting - worse than failing loudly.
+var errSQLServerUpsertUnsupported = errors.New("dialect: MERGE-based upsert is not yet implemented for sqlserver")
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() Name { return SQLServer }
+
+func (sqlServerDialect) SupportsReturning() bool { return true }
+
+func (sqlServerDialect) Upsert(db *gorm.DB, conflictColumns, updateColumns []string) *gorm.DB {
+	db.AddError(errSQLServerUpsertUnsupported)
+	return db
+}
+
+func (sqlServerDialect) Ignore(db *gorm.DB, conflictColumns []string) *gorm.DB {
+	db.AddError(errSQLServerUpsertUnsupported)
+	return db
+}
+
+func (sqlServerDialect) Returning(db *gorm.DB, columns ...string) *gorm.DB {
+	if len(columns) == 0 {
+		return db
+	}
+	return db.Clauses(clause.Returning{Columns: toColumns(columns)})
+}
+
+func toColumns(names []string) []clause.Column {
+	columns := make([]clause.Column, len(names))
+	for i, name := range names {
+		columns[i] = clause.Column{Name: name}
+	}
+	return columns
+}
+
+var registry = map[Name]Dialect{
+	MySQL:     onConflictDialect{name: MySQL, supportsReturning: false},
+	Postgres:  onConflictDialect{name: Postgres, supportsReturning: true},
+	SQLite:    onConflictDialect{name: SQLite, supportsReturning: true},
+	SQLServer: sqlServerDialect{},
+}
+
+// Register overrides or adds a Dialect under name, e.g. for a driver whose
+// Dialector.Name() isn't one of the built-ins but behaves like one of them
+// (OpenGauss reports "opengauss" but is Postgres-wire-compatible).
+func Register(name Name, d Dialect) {
+	registry[name] = d
+}
+
+// Detect resolves the Dialect for a gorm Dialector name as returned by
+// gormDb.Dialector.Name(). Unknown names fall back to the Postgres-style
+// ON CONFLICT dialect, the closest match to standard SQL.
+func Detect(dialectorName string) Dialect {
+	if d, ok := registry[Name(dialectorName)]; ok {
+		return d
+	}
+	return registry[Postgres]
+}
@@ -0,0 +1,238 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrimaryKey constrains the Go types gplus will accept as a table's
+// primary key column value.
+type PrimaryKey interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~string
+}
+
+// PreloadQuery names an association to eager-load and the conditions that
+// scope it, e.g. only preloading a user's non-cancelled orders.
+type PreloadQuery struct {
+	Association string
+	Conditions  []any
+}
+
+// Query builds up a gorm condition for a single entity type T by chaining
+// calls (q.Eq(...).Gt(...).OrderByDesc(...)); buildCondition translates the
+// accumulated builders into the equivalent *gorm.DB calls.
+type Query[T any] struct {
+	DistinctColumns []string
+	SelectColumns   []string
+	OmitColumns     []string
+
+	QueryBuilder strings.Builder
+	QueryArgs    []any
+
+	AndBracketBuilder strings.Builder
+	AndBracketArgs    []any
+
+	OrBracketBuilder strings.Builder
+	OrBracketArgs    []any
+
+	OrderBuilder  strings.Builder
+	GroupBuilder  strings.Builder
+	HavingBuilder strings.Builder
+	HavingArgs    []any
+
+	PreloadAssociations []PreloadQuery
+	JoinsAssociations   []string
+
+	UpdateMap map[string]any
+
+	useOr bool
+}
+
+// NewQuery creates an empty Query for entity type T.
+func NewQuery[T any]() *Query[T] {
+	return &Query[T]{UpdateMap: make(map[string]any)}
+}
+
+// Or makes the next condition combine with OR instead of the default AND.
+// It only affects the single condition that immediately follows it.
+func (q *Query[T]) Or() *Query[T] {
+	q.useOr = true
+	return q
+}
+
+func (q *Query[T]) appendCondition(expr string, args ...any) *Query[T] {
+	if q.QueryBuilder.Len() > 0 {
+		if q.useOr {
+			q.QueryBuilder.WriteString(" OR ")
+		} else {
+			q.QueryBuilder.WriteString(" AND ")
+		}
+	}
+	q.useOr = false
+	q.QueryBuilder.WriteString(expr)
+	q.QueryArgs = append(q.QueryArgs, args...)
+	return q
+}
+
+func (q *Query[T]) Eq(column string, value any) *Query[T] {
+	return q.appendCondition(column+" = ?", value)
+}
+
+func (q *Query[T]) Ne(column string, value any) *Query[T] {
+	return q.appendCondition(column+" <> ?", value)
+}
+
+func (q *Query[T]) Gt(column string, value any) *Query[T] {
+	return q.appendCondition(column+" > ?", value)
+}
+
+func (q *Query[T]) Ge(column string, value any) *Query[T] {
+	return q.appendCondition(column+" >= ?", value)
+}
+
+func (q *Query[T]) Lt(column string, value any) *Query[T] {
+	return q.appendCondition(column+" < ?", value)
+}
+
+func (q *Query[T]) Le(column string, value any) *Query[T] {
+	return q.appendCondition(column+" <= ?", value)
+}
+
+func (q *Query[T]) Like(column string, value any) *Query[T] {
+	return q.appendCondition(column+" LIKE ?", "%"+toStr(value)+"%")
+}
+
+func (q *Query[T]) LeftLike(column string, value any) *Query[T] {
+	return q.appendCondition(column+" LIKE ?", "%"+toStr(value))
+}
+
+func (q *Query[T]) RightLike(column string, value any) *Query[T] {
+	return q.appendCondition(column+" LIKE ?", toStr(value)+"%")
+}
+
+func (q *Query[T]) In(column string, values any) *Query[T] {
+	return q.appendCondition(column+" IN (?)", values)
+}
+
+func (q *Query[T]) NotIn(column string, values any) *Query[T] {
+	return q.appendCondition(column+" NOT IN (?)", values)
+}
+
+func (q *Query[T]) IsNull(column string) *Query[T] {
+	return q.appendCondition(column + " IS NULL")
+}
+
+func (q *Query[T]) IsNotNull(column string) *Query[T] {
+	return q.appendCondition(column + " IS NOT NULL")
+}
+
+func (q *Query[T]) Between(column string, start, end any) *Query[T] {
+	return q.appendCondition(column+" BETWEEN ? AND ?", start, end)
+}
+
+func (q *Query[T]) NotBetween(column string, start, end any) *Query[T] {
+	return q.appendCondition(column+" NOT BETWEEN ? AND ?", start, end)
+}
+
+func (q *Query[T]) OrderByAsc(column string) *Query[T] {
+	if q.OrderBuilder.Len() > 0 {
+		q.OrderBuilder.WriteString(", ")
+	}
+	q.OrderBuilder.WriteString(column + " ASC")
+	return q
+}
+
+func (q *Query[T]) OrderByDesc(column string) *Query[T] {
+	if q.OrderBuilder.Len() > 0 {
+		q.OrderBuilder.WriteString(", ")
+	}
+	q.OrderBuilder.WriteString(column + " DESC")
+	return q
+}
+
+func (q *Query[T]) Group(column string) *Query[T] {
+	if q.GroupBuilder.Len() > 0 {
+		q.GroupBuilder.WriteString(", ")
+	}
+	q.GroupBuilder.WriteString(column)
+	return q
+}
+
+func (q *Query[T]) Having(expr string, args ...any) *Query[T] {
+	if q.HavingBuilder.Len() > 0 {
+		q.HavingBuilder.WriteString(" AND ")
+	}
+	q.HavingBuilder.WriteString(expr)
+	q.HavingArgs = append(q.HavingArgs, args...)
+	return q
+}
+
+// Select restricts the columns returned by the query.
+func (q *Query[T]) Select(columns ...string) *Query[T] {
+	q.SelectColumns = append(q.SelectColumns, columns...)
+	return q
+}
+
+// Set stages column = value for the next Update(q) call.
+func (q *Query[T]) Set(column string, value any) *Query[T] {
+	q.UpdateMap[column] = value
+	return q
+}
+
+// Omit excludes cols from the columns a select query reads, the inverse of
+// Select. Update(q) and Delete(q) do not apply it.
+func (q *Query[T]) Omit(cols ...string) *Query[T] {
+	q.OmitColumns = append(q.OmitColumns, cols...)
+	return q
+}
+
+// Preload eager-loads assoc (a field name on T, as in gorm's Preload),
+// optionally scoped by conds the same way gorm's own Preload accepts them.
+func (q *Query[T]) Preload(assoc string, conds ...any) *Query[T] {
+	q.PreloadAssociations = append(q.PreloadAssociations, PreloadQuery{Association: assoc, Conditions: conds})
+	return q
+}
+
+// PreloadWithQuery eager-loads assoc scoped by subQ's accumulated
+// conditions, for cases too involved for gorm's inline condition args.
+func (q *Query[T]) PreloadWithQuery(assoc string, subQ *Query[any]) *Query[T] {
+	var conds []any
+	if subQ != nil && subQ.QueryBuilder.Len() > 0 {
+		conds = append(conds, subQ.QueryBuilder.String())
+		conds = append(conds, subQ.QueryArgs...)
+	}
+	q.PreloadAssociations = append(q.PreloadAssociations, PreloadQuery{Association: assoc, Conditions: conds})
+	return q
+}
+
+// Joins eager-loads assoc via a SQL JOIN instead of a separate query, as in
+// gorm's own Joins. Unlike Preload this only works for to-one associations.
+func (q *Query[T]) Joins(assoc string) *Query[T] {
+	q.JoinsAssociations = append(q.JoinsAssociations, assoc)
+	return q
+}
+
+func toStr(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope is a reusable query fragment, analogous to gorm's db.Scopes: a
+// function that takes a *Query[T] and returns it with more conditions
+// applied. SelectList, SelectPage, Delete and Update accept variadic
+// scopes so repositories can compose filters instead of repeating
+// q.Eq("owner_id", uid) at every call site.
+type Scope[T any] func(*Query[T]) *Query[T]
+
+// Compose folds scopes into a single Scope, applying them in order.
+func Compose[T any](scopes ...Scope[T]) Scope[T] {
+	return func(q *Query[T]) *Query[T] {
+		return applyScopes(q, scopes)
+	}
+}
+
+func applyScopes[T any](q *Query[T], scopes []Scope[T]) *Query[T] {
+	for _, scope := range scopes {
+		if scope != nil {
+			q = scope(q)
+		}
+	}
+	return q
+}
+
+// SoftDeleted restricts the query to rows where column is non-null,
+// e.g. SoftDeleted[T]("deleted_at").
+func SoftDeleted[T any](column string) Scope[T] {
+	return func(q *Query[T]) *Query[T] {
+		q.IsNotNull(column)
+		return q
+	}
+}
+
+// OwnedBy restricts the query to rows whose column equals ownerID.
+func OwnedBy[T any](column string, ownerID any) Scope[T] {
+	return func(q *Query[T]) *Query[T] {
+		q.Eq(column, ownerID)
+		return q
+	}
+}
+
+// CreatedBetween restricts the query to rows whose column falls in [from, to].
+func CreatedBetween[T any](column string, from, to time.Time) Scope[T] {
+	return func(q *Query[T]) *Query[T] {
+		q.Between(column, from, to)
+		return q
+	}
+}
+
+// Search restricts the query to rows where any of cols LIKE %term%. The
+// per-column comparisons are OR'd together inside their own parenthesized
+// group and that group is AND'd onto whatever conditions q already has, so
+// composing Search with other scopes (e.g. Compose(OwnedBy[T]("owner_id",
+// uid), Search[T](term, cols...))) narrows the result set instead of
+// loosening it.
+func Search[T any](term string, cols ...string) Scope[T] {
+	return func(q *Query[T]) *Query[T] {
+		if term == "" || len(cols) == 0 {
+			return q
+		}
+		var group strings.Builder
+		args := make([]any, 0, len(cols))
+		for i, col := range cols {
+			if i > 0 {
+				group.WriteString(" OR ")
+			}
+			group.WriteString(col + " LIKE ?")
+			args = append(args, "%"+term+"%")
+		}
+		return q.appendCondition("("+group.String()+")", args...)
+	}
+}
@@ -0,0 +1,277 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Session is gplus's full method set bound to an explicit *gorm.DB instead
+// of the package-level gormDb, so callers aren't forced onto a single
+// global connection (multi-tenant DBs, read replicas, tests with an
+// isolated handle, ...).
+type Session struct {
+	db *gorm.DB
+}
+
+// NewSession binds a Session to db. It does not call db.WithContext; pass
+// a db already scoped the way you want (see WithContext/Transaction).
+func NewSession(db *gorm.DB) *Session {
+	return &Session{db: db}
+}
+
+// WithContext returns a Session bound to s.db.WithContext(ctx).
+func (s *Session) WithContext(ctx context.Context) *Session {
+	return &Session{db: s.db.WithContext(ctx)}
+}
+
+// DB returns the *gorm.DB this Session is bound to.
+func (s *Session) DB() *gorm.DB {
+	return s.db
+}
+
+// The functions below are Session's method set. They're package-level
+// rather than methods on Session because Go methods can't introduce their
+// own type parameters; SessionInsert[T](s, entity) is the generic
+// equivalent of s.Insert(entity) in a non-generic API.
+
+func SessionInsert[T any](s *Session, entity *T) *gorm.DB {
+	return insertOn(s.db, entity)
+}
+
+func SessionInsertBatch[T any](s *Session, entities []*T) *gorm.DB {
+	return insertBatchOn(s.db, entities, defaultBatchSize)
+}
+
+func SessionInsertBatchSize[T any](s *Session, entities []*T, batchSize int) *gorm.DB {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return insertBatchOn(s.db, entities, batchSize)
+}
+
+func SessionDeleteById[T any, K PrimaryKey](s *Session, id K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdOn[T](s.db, id, primaryKeyColumn...)
+}
+
+func SessionDeleteByIds[T any, K PrimaryKey](s *Session, ids []K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdsOn[T](s.db, ids, primaryKeyColumn...)
+}
+
+func SessionDelete[T any](s *Session, q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	return deleteOn(s.db, applyScopes(q, scopes))
+}
+
+func SessionUpdateById[T any, K PrimaryKey](s *Session, entity *T, id K, primaryKeyColumn ...string) *gorm.DB {
+	return updateByIdOn(s.db, entity, id, primaryKeyColumn...)
+}
+
+func SessionUpdate[T any](s *Session, q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	return updateOn(s.db, applyScopes(q, scopes))
+}
+
+func SessionSelectById[T any, K PrimaryKey](s *Session, id K) (*T, *gorm.DB) {
+	return selectByIdOn[T, K](s.db, id)
+}
+
+func SessionSelectByIds[T any, K PrimaryKey](s *Session, ids []K, primaryKeyColumn ...string) ([]*T, *gorm.DB) {
+	return selectByIdsOn[T, K](s.db, ids, primaryKeyColumn...)
+}
+
+func SessionSelectOne[T any](s *Session, q *Query[T]) (*T, *gorm.DB) {
+	return selectOneOn(s.db, q)
+}
+
+func SessionSelectList[T any](s *Session, q *Query[T], scopes ...Scope[T]) ([]*T, *gorm.DB) {
+	return selectListOn(s.db, applyScopes(q, scopes))
+}
+
+func SessionSelectListModel[T any, R any](s *Session, q *Query[T]) ([]*R, *gorm.DB) {
+	return selectListModelOn[T, R](s.db, q)
+}
+
+func SessionSelectPage[T any](s *Session, page *Page[T], q *Query[T], scopes ...Scope[T]) (*Page[T], *gorm.DB) {
+	return selectPageOn(s.db, page, applyScopes(q, scopes))
+}
+
+func SessionSelectPageModel[T any, R any](s *Session, page *Page[R], q *Query[T]) (*Page[R], *gorm.DB) {
+	return selectPageModelOn[T, R](s.db, page, q)
+}
+
+func SessionSelectCount[T any](s *Session, q *Query[T]) (int64, *gorm.DB) {
+	return selectCountOn(s.db, q)
+}
+
+func SessionSelectCursorPage[T any](s *Session, cp *CursorPage[T], q *Query[T], cursor string, columns ...CursorColumn) (*CursorPage[T], *gorm.DB) {
+	return selectCursorPageOn(s.db, cp, q, cursor, columns...)
+}
+
+func SessionInsertOrUpdate[T any](s *Session, entity *T, conflictColumns []string, updateColumns []string) *gorm.DB {
+	return insertOrUpdateOn(s.db, entity, conflictColumns, updateColumns)
+}
+
+func SessionInsertOrIgnore[T any](s *Session, entity *T, conflictColumns []string) *gorm.DB {
+	return insertOrIgnoreOn(s.db, entity, conflictColumns)
+}
+
+func SessionInsertBatchReturning[T any](s *Session, entities []*T, returningColumns ...string) *gorm.DB {
+	return insertBatchReturningOn(s.db, entities, returningColumns...)
+}
+
+type dbContextKey struct{}
+
+// WithDB stores db in ctx so a later call to SessionFromContext (and the
+// Ctx-suffixed package functions) picks it up instead of gormDb. This is
+// how Transaction propagates its *gorm.DB to nested calls made with the
+// same context.
+func WithDB(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, dbContextKey{}, db)
+}
+
+// SessionFromContext returns a Session bound to the *gorm.DB stashed in ctx
+// by WithDB/Transaction, or to gormDb.WithContext(ctx) if none was stashed.
+func SessionFromContext(ctx context.Context) *Session {
+	if db, ok := ctx.Value(dbContextKey{}).(*gorm.DB); ok {
+		return NewSession(db)
+	}
+	return NewSession(gormDb.WithContext(ctx))
+}
+
+func dbFromContext(ctx context.Context) *gorm.DB {
+	return SessionFromContext(ctx).db
+}
+
+// Transaction runs fn inside a database transaction, passing fn a Session
+// bound to the transactional *gorm.DB plus a derived context carrying that
+// same *gorm.DB via WithDB. Any Ctx-suffixed gplus call made with that
+// derived context (instead of the Session fn receives) joins the same
+// transaction automatically. fn's returned error rolls the transaction
+// back; a nil return commits it.
+func Transaction(ctx context.Context, fn func(txCtx context.Context, s *Session) error) error {
+	return dbFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithDB(ctx, tx), NewSession(tx))
+	})
+}
+
+func InsertCtx[T any](ctx context.Context, entity *T) *gorm.DB {
+	db := dbFromContext(ctx)
+	applyAuditFields(ctx, db, entity, true)
+	return insertOn(db, entity)
+}
+
+func InsertBatchCtx[T any](ctx context.Context, entities []*T) *gorm.DB {
+	db := dbFromContext(ctx)
+	for _, entity := range entities {
+		applyAuditFields(ctx, db, entity, true)
+	}
+	return insertBatchOn(db, entities, defaultBatchSize)
+}
+
+func InsertBatchSizeCtx[T any](ctx context.Context, entities []*T, batchSize int) *gorm.DB {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	db := dbFromContext(ctx)
+	for _, entity := range entities {
+		applyAuditFields(ctx, db, entity, true)
+	}
+	return insertBatchOn(db, entities, batchSize)
+}
+
+func DeleteByIdCtx[T any, K PrimaryKey](ctx context.Context, id K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdOn[T](dbFromContext(ctx), id, primaryKeyColumn...)
+}
+
+func DeleteByIdsCtx[T any, K PrimaryKey](ctx context.Context, ids []K, primaryKeyColumn ...string) *gorm.DB {
+	return deleteByIdsOn[T](dbFromContext(ctx), ids, primaryKeyColumn...)
+}
+
+func DeleteCtx[T any](ctx context.Context, q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	return deleteOn(dbFromContext(ctx), applyScopes(q, scopes))
+}
+
+func UpdateByIdCtx[T any, K PrimaryKey](ctx context.Context, entity *T, id K, primaryKeyColumn ...string) *gorm.DB {
+	db := dbFromContext(ctx)
+	applyAuditFields(ctx, db, entity, false)
+	return updateByIdOn(db, entity, id, primaryKeyColumn...)
+}
+
+func UpdateCtx[T any](ctx context.Context, q *Query[T], scopes ...Scope[T]) *gorm.DB {
+	q = applyScopes(q, scopes)
+	db := dbFromContext(ctx)
+	applyAuditToUpdateMap(ctx, db, q)
+	return updateOn(db, q)
+}
+
+func SelectByIdCtx[T any, K PrimaryKey](ctx context.Context, id K) (*T, *gorm.DB) {
+	return selectByIdOn[T, K](dbFromContext(ctx), id)
+}
+
+func SelectByIdsCtx[T any, K PrimaryKey](ctx context.Context, ids []K, primaryKeyColumn ...string) ([]*T, *gorm.DB) {
+	return selectByIdsOn[T, K](dbFromContext(ctx), ids, primaryKeyColumn...)
+}
+
+func SelectOneCtx[T any](ctx context.Context, q *Query[T]) (*T, *gorm.DB) {
+	return selectOneOn(dbFromContext(ctx), q)
+}
+
+func SelectListCtx[T any](ctx context.Context, q *Query[T], scopes ...Scope[T]) ([]*T, *gorm.DB) {
+	return selectListOn(dbFromContext(ctx), applyScopes(q, scopes))
+}
+
+func SelectListModelCtx[T any, R any](ctx context.Context, q *Query[T]) ([]*R, *gorm.DB) {
+	return selectListModelOn[T, R](dbFromContext(ctx), q)
+}
+
+func SelectPageCtx[T any](ctx context.Context, page *Page[T], q *Query[T], scopes ...Scope[T]) (*Page[T], *gorm.DB) {
+	return selectPageOn(dbFromContext(ctx), page, applyScopes(q, scopes))
+}
+
+func SelectPageModelCtx[T any, R any](ctx context.Context, page *Page[R], q *Query[T]) (*Page[R], *gorm.DB) {
+	return selectPageModelOn[T, R](dbFromContext(ctx), page, q)
+}
+
+func SelectCountCtx[T any](ctx context.Context, q *Query[T]) (int64, *gorm.DB) {
+	return selectCountOn(dbFromContext(ctx), q)
+}
+
+func SelectCursorPageCtx[T any](ctx context.Context, cp *CursorPage[T], q *Query[T], cursor string, columns ...CursorColumn) (*CursorPage[T], *gorm.DB) {
+	return selectCursorPageOn(dbFromContext(ctx), cp, q, cursor, columns...)
+}
+
+func InsertOrUpdateCtx[T any](ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) *gorm.DB {
+	db := dbFromContext(ctx)
+	applyAuditFields(ctx, db, entity, true)
+	return insertOrUpdateOn(db, entity, conflictColumns, updateColumns)
+}
+
+func InsertOrIgnoreCtx[T any](ctx context.Context, entity *T, conflictColumns []string) *gorm.DB {
+	db := dbFromContext(ctx)
+	applyAuditFields(ctx, db, entity, true)
+	return insertOrIgnoreOn(db, entity, conflictColumns)
+}
+
+func InsertBatchReturningCtx[T any](ctx context.Context, entities []*T, returningColumns ...string) *gorm.DB {
+	db := dbFromContext(ctx)
+	for _, entity := range entities {
+		applyAuditFields(ctx, db, entity, true)
+	}
+	return insertBatchReturningOn(db, entities, returningColumns...)
+}
@@ -0,0 +1,257 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tagquery builds a *gplus.Query[T] directly from a request struct
+// whose fields carry `query` tags describing filter semantics. It is meant
+// to sit in front of HTTP handlers: bind a Gin/Echo query string onto a
+// plain struct and hand that struct to Build to get a ready-to-use query,
+// with no manual chain of Eq/Gt/Like calls at the call site.
+package tagquery
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/acmestack/gorm-plus/gplus"
+)
+
+// tag is the struct tag key read by this package, e.g.:
+//
+//	Name string `query:"name,eq"`
+//	Age  int    `query:"age,gte,required"`
+const tag = "query"
+
+// op identifies the filter semantics carried by a `query` tag.
+type op string
+
+const (
+	opEq      op = "eq"
+	opNeq     op = "neq"
+	opGt      op = "gt"
+	opGte     op = "gte"
+	opLt      op = "lt"
+	opLte     op = "lte"
+	opLike    op = "like"
+	opLLike   op = "llike"
+	opRLike   op = "rlike"
+	opIn      op = "in"
+	opNotIn   op = "notin"
+	opIsNull  op = "isnull"
+	opNotNull op = "notnull"
+	opBetween op = "between"
+)
+
+// Meta tag values recognised on the "query" tag in place of a column name.
+// They don't add a filter condition, they configure sorting/pagination.
+const (
+	metaSort    = "sort"
+	metaPage    = "page"
+	metaPerPage = "per_page"
+	metaLimit   = "limit"
+	metaOffset  = "offset"
+)
+
+const required = "required"
+
+// Build walks req's fields via reflection and returns a *gplus.Query[T]
+// with one condition per tagged, non-zero field (fields marked "required"
+// are included even when zero-valued), plus a *gplus.Page[T] derived from
+// the "page"/"per_page" or "limit"/"offset" meta tags. The pair is meant
+// to be passed straight to gplus.SelectPage(page, query); callers that only
+// need the filter (e.g. for gplus.SelectList) can simply ignore the page.
+func Build[T any](req any) (*gplus.Query[T], *gplus.Page[T], error) {
+	q := gplus.NewQuery[T]()
+
+	v := reflect.ValueOf(req)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("tagquery: Build expects a struct or pointer to struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		rawTag, ok := field.Tag.Lookup(tag)
+		if !ok || rawTag == "" || rawTag == "-" {
+			continue
+		}
+
+		parts := strings.Split(rawTag, ",")
+		fieldValue := v.Field(i)
+
+		switch parts[0] {
+		case metaSort:
+			applySort(q, fieldValue)
+			continue
+		case metaPage, metaPerPage, metaLimit, metaOffset:
+			continue
+		}
+
+		if len(parts) < 2 {
+			return nil, nil, fmt.Errorf("tagquery: field %s has malformed tag %q, expected \"column,op\"", field.Name, rawTag)
+		}
+		column := parts[0]
+		o := op(parts[1])
+		isRequired := len(parts) > 2 && parts[2] == required
+
+		if !isRequired && fieldValue.IsZero() {
+			continue
+		}
+
+		if err := applyCondition(q, column, o, fieldValue); err != nil {
+			return nil, nil, fmt.Errorf("tagquery: field %s: %w", field.Name, err)
+		}
+	}
+
+	return q, buildPage[T](v, t), nil
+}
+
+// buildPage derives a *gplus.Page[T] from the "page"/"per_page" or
+// "limit"/"offset" meta tags. limit/offset are converted to page/per_page
+// (gplus paginates by page, not raw offset): page is offset/limit + 1, so an
+// offset that is not a multiple of limit is rounded down to one that is.
+func buildPage[T any](v reflect.Value, t reflect.Type) *gplus.Page[T] {
+	page, perPage, limit, offset := readPaging(v, t)
+	if perPage == 0 && limit > 0 {
+		perPage = limit
+		if page == 0 {
+			page = offset/limit + 1
+		}
+	}
+	if page == 0 {
+		page = 1
+	}
+	return gplus.NewPage[T](page, perPage)
+}
+
+func applyCondition[T any](q *gplus.Query[T], column string, o op, fieldValue reflect.Value) error {
+	value := fieldValue.Interface()
+	switch o {
+	case opEq:
+		q.Eq(column, value)
+	case opNeq:
+		q.Ne(column, value)
+	case opGt:
+		q.Gt(column, value)
+	case opGte:
+		q.Ge(column, value)
+	case opLt:
+		q.Lt(column, value)
+	case opLte:
+		q.Le(column, value)
+	case opLike:
+		q.Like(column, value)
+	case opLLike:
+		q.LeftLike(column, value)
+	case opRLike:
+		q.RightLike(column, value)
+	case opIn:
+		q.In(column, toSlice(fieldValue))
+	case opNotIn:
+		q.NotIn(column, toSlice(fieldValue))
+	case opIsNull:
+		q.IsNull(column)
+	case opNotNull:
+		q.IsNotNull(column)
+	case opBetween:
+		between, err := toBetween(fieldValue)
+		if err != nil {
+			return err
+		}
+		q.Between(column, between[0], between[1])
+	default:
+		return fmt.Errorf("unsupported query op %q", o)
+	}
+	return nil
+}
+
+// applySort translates a "name-,age+" style string into ORDER BY clauses,
+// where a trailing "-" means DESC and "+" (or no suffix) means ASC.
+func applySort[T any](q *gplus.Query[T], fieldValue reflect.Value) {
+	raw, ok := fieldValue.Interface().(string)
+	if !ok || raw == "" {
+		return
+	}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(part, "-"):
+			q.OrderByDesc(strings.TrimSuffix(part, "-"))
+		case strings.HasSuffix(part, "+"):
+			q.OrderByAsc(strings.TrimSuffix(part, "+"))
+		default:
+			q.OrderByAsc(part)
+		}
+	}
+}
+
+func readPaging(v reflect.Value, t reflect.Type) (page, perPage, limit, offset int) {
+	for i := 0; i < t.NumField(); i++ {
+		rawTag, ok := t.Field(i).Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		n, _ := toInt(v.Field(i))
+		switch strings.Split(rawTag, ",")[0] {
+		case metaPage:
+			page = n
+		case metaPerPage:
+			perPage = n
+		case metaLimit:
+			limit = n
+		case metaOffset:
+			offset = n
+		}
+	}
+	return
+}
+
+func toInt(v reflect.Value) (int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), nil
+	case reflect.String:
+		return strconv.Atoi(v.String())
+	default:
+		return 0, fmt.Errorf("tagquery: cannot convert %s to int", v.Kind())
+	}
+}
+
+func toSlice(v reflect.Value) []any {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []any{v.Interface()}
+	}
+	out := make([]any, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		out[i] = v.Index(i).Interface()
+	}
+	return out
+}
+
+func toBetween(v reflect.Value) ([2]any, error) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() != 2 {
+		return [2]any{}, fmt.Errorf("between tag requires a 2-element slice")
+	}
+	return [2]any{v.Index(0).Interface(), v.Index(1).Interface()}, nil
+}
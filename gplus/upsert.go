@@ -0,0 +1,62 @@
+/*
+ * Licensed to the AcmeStack under one or more contributor license
+ * agreements. See the NOTICE file distributed with this work for
+ * additional information regarding copyright ownership.
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gplus
+
+import "gorm.io/gorm"
+
+// InsertOrUpdate inserts entity, and on a conflict against conflictColumns
+// (typically a primary or unique key) updates updateColumns instead. It
+// emits ON CONFLICT ... DO UPDATE on Postgres/SQLite and ON DUPLICATE KEY
+// UPDATE on MySQL, per currentDialect. SQL Server has no such translation
+// yet; resultDb.Error is set to a descriptive error on that dialect rather
+// than silently falling back to a plain, non-upserting INSERT.
+func InsertOrUpdate[T any](entity *T, conflictColumns []string, updateColumns []string) *gorm.DB {
+	return insertOrUpdateOn(gormDb, entity, conflictColumns, updateColumns)
+}
+
+// InsertOrIgnore inserts entity, silently skipping it on a conflict against
+// conflictColumns rather than returning a duplicate-key error.
+func InsertOrIgnore[T any](entity *T, conflictColumns []string) *gorm.DB {
+	return insertOrIgnoreOn(gormDb, entity, conflictColumns)
+}
+
+// InsertBatchReturning batch-inserts entities and, on dialects that support
+// RETURNING (Postgres, SQLite, SQL Server), populates returningColumns
+// (typically the auto-generated primary key) back into each entity. MySQL
+// doesn't support RETURNING; on it this behaves like InsertBatch.
+func InsertBatchReturning[T any](entities []*T, returningColumns ...string) *gorm.DB {
+	return insertBatchReturningOn(gormDb, entities, returningColumns...)
+}
+
+func insertOrUpdateOn[T any](db *gorm.DB, entity *T, conflictColumns []string, updateColumns []string) *gorm.DB {
+	resultDb := currentDialect.Upsert(db, conflictColumns, updateColumns).Create(entity)
+	return resultDb
+}
+
+func insertOrIgnoreOn[T any](db *gorm.DB, entity *T, conflictColumns []string) *gorm.DB {
+	resultDb := currentDialect.Ignore(db, conflictColumns).Create(entity)
+	return resultDb
+}
+
+func insertBatchReturningOn[T any](db *gorm.DB, entities []*T, returningColumns ...string) *gorm.DB {
+	if len(entities) == 0 {
+		return db
+	}
+	resultDb := currentDialect.Returning(db, returningColumns...).CreateInBatches(entities, defaultBatchSize)
+	return resultDb
+}